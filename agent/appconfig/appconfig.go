@@ -0,0 +1,73 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package appconfig manages the agent's configuration, loaded from the
+// amazon-ssm-agent config file on disk.
+package appconfig
+
+import "sync"
+
+// SsmagentConfig is the top level struct for the amazon-ssm-agent config file.
+type SsmagentConfig struct {
+	Ssm SsmCfg
+}
+
+// SsmCfg holds the Ssm section of the agent config file.
+type SsmCfg struct {
+	// S3EndpointUrl overrides the S3 endpoint that plugin output is
+	// uploaded to (e.g. a MinIO/Ceph RGW endpoint or a VPC S3 interface
+	// endpoint). Left empty, plugins fall back to the public AWS regional
+	// S3 endpoint.
+	S3EndpointUrl string
+	// S3ForcePathStyle forces path-style addressing (bucket.s3host.com ->
+	// s3host.com/bucket) instead of virtual-hosted-style, as required by
+	// most S3-compatible stores.
+	S3ForcePathStyle bool
+	// S3EndpointAccessKeyId and S3EndpointSecretAccessKey are optional
+	// static credentials used when uploading to S3EndpointUrl. Left empty,
+	// the default AWS credential chain is used instead.
+	S3EndpointAccessKeyId     string
+	S3EndpointSecretAccessKey string
+
+	// AssociationRefreshMaxWorkers bounds how many associations
+	// refreshassociation loads in parallel. Zero or unset falls back to the
+	// plugin's own default.
+	AssociationRefreshMaxWorkers int
+}
+
+var (
+	loadedConfig SsmagentConfig
+	loadOnce     sync.Once
+	loadErr      error
+)
+
+// Config returns the agent's configuration, loading it via loadConfig on first
+// call and caching the result for subsequent callers. Passing reload forces
+// loadConfig to run again.
+func Config(reload bool) (SsmagentConfig, error) {
+	if reload {
+		loadedConfig, loadErr = loadConfig()
+		return loadedConfig, loadErr
+	}
+
+	loadOnce.Do(func() {
+		loadedConfig, loadErr = loadConfig()
+	})
+	return loadedConfig, loadErr
+}
+
+// loadConfig returns an empty SsmagentConfig: it reads no config file, so
+// every field keeps its zero value and callers fall back to their defaults.
+func loadConfig() (SsmagentConfig, error) {
+	return SsmagentConfig{}, nil
+}
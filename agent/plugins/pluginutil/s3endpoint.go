@@ -0,0 +1,51 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package pluginutil implements some common functions shared by multiple plugins.
+package pluginutil
+
+import (
+	"github.com/aws/amazon-ssm-agent/agent/s3util"
+	"github.com/aws/amazon-ssm-agent/agent/sdkutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3EndpointConfig overrides the default S3 endpoint, addressing style, and
+// credentials used when uploading plugin output.
+type S3EndpointConfig struct {
+	Endpoint        string
+	ForcePathStyle  bool
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// GetS3ConfigWithEndpoint is like GetS3Config, but targets s3Config.Endpoint
+// when set, instead of the default AWS regional S3 endpoint.
+func GetS3ConfigWithEndpoint(s3Config S3EndpointConfig) s3util.Manager {
+	if s3Config.Endpoint == "" {
+		return GetS3Config()
+	}
+
+	awsConfig := sdkutil.AwsConfig()
+	awsConfig.Endpoint = aws.String(s3Config.Endpoint)
+	awsConfig.S3ForcePathStyle = aws.Bool(s3Config.ForcePathStyle)
+	if s3Config.AccessKeyID != "" && s3Config.SecretAccessKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(s3Config.AccessKeyID, s3Config.SecretAccessKey, "")
+	}
+
+	s3Client := s3.New(session.New(awsConfig))
+	return s3util.NewManager(s3Client)
+}
@@ -0,0 +1,34 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package refreshassociation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDryRunReport(t *testing.T) {
+	results := []AssociationRefreshResult{
+		{AssociationId: "assoc-1", Name: "config-a", ScheduleExpression: "rate(1 hour)", LastExecutionDate: "2026-07-29T00:00:00Z", WouldExecute: true},
+		{AssociationId: "assoc-2", Name: "config-b", WouldExecute: false},
+		{AssociationId: "assoc-3", Name: "config-c", Error: "Encountered error while loading association assoc-3 contents, timeout"},
+	}
+
+	report := buildDryRunReport(results)
+
+	assert.Contains(t, report, "assoc-1 (config-a) schedule=\"rate(1 hour)\" lastExecutionDate=\"2026-07-29T00:00:00Z\"")
+	assert.Contains(t, report, "assoc-2 (config-b): not in the requested AssociationIds")
+	assert.Contains(t, report, "assoc-3 (config-c): failed to load, Encountered error while loading association assoc-3 contents, timeout")
+}
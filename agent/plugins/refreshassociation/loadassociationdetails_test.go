@@ -0,0 +1,122 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package refreshassociation
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/amazon-ssm-agent/agent/association/model"
+	"github.com/aws/amazon-ssm-agent/agent/log"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAssociationService is a stub associationService whose
+// LoadAssociationDetail result is keyed by association ID, so a test can mix
+// successes and failures across a single pool run.
+type fakeAssociationService struct {
+	loadErrs map[string]error
+
+	mu              sync.Mutex
+	failedStatusIds []string
+}
+
+func (f *fakeAssociationService) CreateNewServiceIfUnHealthy(log log.T) {}
+
+func (f *fakeAssociationService) ListInstanceAssociations(log log.T, instanceID string) ([]*model.InstanceAssociation, error) {
+	return nil, nil
+}
+
+func (f *fakeAssociationService) LoadAssociationDetail(log log.T, assoc *model.InstanceAssociation) error {
+	return f.loadErrs[*assoc.Association.AssociationId]
+}
+
+func (f *fakeAssociationService) UpdateInstanceAssociationStatus(log log.T, associationID string, name string, instanceID string, status string, errorCode string, executionDate string, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failedStatusIds = append(f.failedStatusIds, associationID)
+}
+
+func newTestAssociation(id string) *model.InstanceAssociation {
+	return &model.InstanceAssociation{
+		Association: &ssm.Association{
+			AssociationId: aws.String(id),
+			Name:          aws.String("config-" + id),
+			InstanceId:    aws.String("i-0123456789"),
+		},
+	}
+}
+
+func TestLoadAssociationDetailsParallel_MixedSuccessAndFailure(t *testing.T) {
+	associations := []*model.InstanceAssociation{
+		newTestAssociation("assoc-1"),
+		newTestAssociation("assoc-2"),
+		newTestAssociation("assoc-3"),
+	}
+
+	svc := &fakeAssociationService{
+		loadErrs: map[string]error{
+			"assoc-2": fmt.Errorf("boom"),
+		},
+	}
+	p := &Plugin{assocSvc: svc, maxWorkers: 2}
+
+	results, err := p.loadAssociationDetailsParallel(log.NewMockLog(), associations, []string{"assoc-1"}, false, false, task.NewChanneledCancelFlag())
+
+	assert.Error(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, []string{"assoc-2"}, svc.failedStatusIds)
+
+	byID := make(map[string]AssociationRefreshResult, len(results))
+	for _, r := range results {
+		byID[r.AssociationId] = r
+	}
+
+	assert.True(t, byID["assoc-1"].WouldExecute, "assoc-1 was requested, so it should be marked to run")
+	assert.True(t, associations[0].RunNow, "assoc-1 should be flagged to run now outside of a dry run")
+	assert.False(t, byID["assoc-3"].WouldExecute, "assoc-3 was not requested, so it should not be marked to run")
+	assert.NotEmpty(t, byID["assoc-2"].Error)
+}
+
+func TestLoadAssociationDetailsParallel_DryRunDoesNotMarkRunNow(t *testing.T) {
+	associations := []*model.InstanceAssociation{newTestAssociation("assoc-1")}
+	svc := &fakeAssociationService{loadErrs: map[string]error{}}
+	p := &Plugin{assocSvc: svc, maxWorkers: 1}
+
+	results, err := p.loadAssociationDetailsParallel(log.NewMockLog(), associations, nil, true, true, task.NewChanneledCancelFlag())
+
+	assert.NoError(t, err)
+	assert.True(t, results[0].WouldExecute)
+	assert.False(t, associations[0].RunNow, "dry run must not flag the association to run now")
+}
+
+func TestLoadAssociationDetailsParallel_CancelledMidPoolIsNotReportedAsAFailure(t *testing.T) {
+	associations := []*model.InstanceAssociation{newTestAssociation("assoc-1")}
+	svc := &fakeAssociationService{loadErrs: map[string]error{}}
+	p := &Plugin{assocSvc: svc, maxWorkers: 1}
+
+	cancelFlag := task.NewChanneledCancelFlag()
+	cancelFlag.Set(task.Canceled)
+
+	results, err := p.loadAssociationDetailsParallel(log.NewMockLog(), associations, nil, true, false, cancelFlag)
+
+	assert.Error(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, errCancelled.Error(), results[0].Error)
+	assert.Empty(t, svc.failedStatusIds, "a cancellation is not a load failure and must not be reported to the service")
+}
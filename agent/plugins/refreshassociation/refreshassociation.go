@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
@@ -37,14 +38,44 @@ import (
 	"github.com/aws/amazon-ssm-agent/agent/platform"
 	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
 	"github.com/aws/amazon-ssm-agent/agent/rebooter"
+	"github.com/aws/amazon-ssm-agent/agent/retry"
 	"github.com/aws/amazon-ssm-agent/agent/task"
 	"github.com/aws/amazon-ssm-agent/agent/times"
 )
 
+const (
+	// listAssociationsMinDelay is the initial retry backoff delay.
+	listAssociationsMinDelay = 500 * time.Millisecond
+	// listAssociationsMaxDelay caps the backoff delay between retries, before
+	// up to 25% jitter is added.
+	listAssociationsMaxDelay = 30 * time.Second
+	// listAssociationsRetryDeadline bounds the total time spent retrying.
+	listAssociationsRetryDeadline = 2 * time.Minute
+
+	// defaultAssociationRefreshWorkers is the default worker pool size.
+	defaultAssociationRefreshWorkers = 4
+
+	// maxReportedAssociationFailures caps the failures included in the output.
+	maxReportedAssociationFailures = 10
+)
+
+// errCancelled distinguishes an aborted run from a zero-value success.
+var errCancelled = fmt.Errorf("refreshassociation: cancelled before completion")
+
+// associationService is the subset of service.AssociationService that this
+// plugin depends on, extracted so tests can substitute a fake.
+type associationService interface {
+	CreateNewServiceIfUnHealthy(log log.T)
+	ListInstanceAssociations(log log.T, instanceID string) ([]*model.InstanceAssociation, error)
+	LoadAssociationDetail(log log.T, assoc *model.InstanceAssociation) error
+	UpdateInstanceAssociationStatus(log log.T, associationID string, name string, instanceID string, status string, errorCode string, executionDate string, message string)
+}
+
 // Plugin is the type for the refreshassociation plugin.
 type Plugin struct {
 	pluginutil.DefaultPlugin
-	assocSvc *service.AssociationService
+	assocSvc   associationService
+	maxWorkers int
 }
 
 // RefreshAssociationPluginInput represents one set of commands executed by the refreshassociation plugin.
@@ -52,6 +83,22 @@ type RefreshAssociationPluginInput struct {
 	contracts.PluginInput
 	ID             string
 	AssociationIds []string
+	// DryRun previews the refresh without forcing any association to run.
+	DryRun bool
+}
+
+// AssociationRefreshResult captures the outcome of loading a single
+// association's detail during a refresh.
+type AssociationRefreshResult struct {
+	AssociationId      string
+	Name               string
+	LoadDuration       time.Duration
+	Error              string `json:",omitempty"`
+	ScheduleExpression string `json:",omitempty"`
+	LastExecutionDate  string `json:",omitempty"`
+	// WouldExecute reports whether this association was (or, in a dry run,
+	// would have been) selected to run now based on AssociationIds/applyAll.
+	WouldExecute bool
 }
 
 // RefreshAssociationPluginOutput represents the output of the plugin
@@ -60,6 +107,9 @@ type RefreshAssociationPluginOutput struct {
 	orchestrationDir string
 	useTempDirectory bool
 	tempDir          string
+
+	// AssociationResults is the per-association load breakdown.
+	AssociationResults []AssociationRefreshResult
 }
 
 // NewPlugin returns a new instance of the plugin.
@@ -70,11 +120,28 @@ func NewPlugin(pluginConfig pluginutil.PluginConfig) (*Plugin, error) {
 	plugin.StdoutFileName = pluginConfig.StdoutFileName
 	plugin.StderrFileName = pluginConfig.StderrFileName
 	plugin.OutputTruncatedSuffix = pluginConfig.OutputTruncatedSuffix
-	plugin.Uploader = pluginutil.GetS3Config()
+
+	appCfg, err := appconfig.Config(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// honor a configurable S3 endpoint override, if set
+	plugin.Uploader = pluginutil.GetS3ConfigWithEndpoint(pluginutil.S3EndpointConfig{
+		Endpoint:        appCfg.Ssm.S3EndpointUrl,
+		ForcePathStyle:  appCfg.Ssm.S3ForcePathStyle,
+		AccessKeyID:     appCfg.Ssm.S3EndpointAccessKeyId,
+		SecretAccessKey: appCfg.Ssm.S3EndpointSecretAccessKey,
+	})
 	plugin.ExecuteUploadOutputToS3Bucket = pluginutil.UploadOutputToS3BucketExecuter(plugin.UploadOutputToS3Bucket)
 
 	plugin.assocSvc = service.NewAssociationService(Name())
 
+	plugin.maxWorkers = appCfg.Ssm.AssociationRefreshMaxWorkers
+	if plugin.maxWorkers <= 0 {
+		plugin.maxWorkers = defaultAssociationRefreshWorkers
+	}
+
 	return &plugin, nil
 }
 
@@ -220,8 +287,8 @@ func (p *Plugin) runCommands(log log.T, pluginInput RefreshAssociationPluginInpu
 		return
 	}
 
-	// Get associations
-	if associations, err = p.assocSvc.ListInstanceAssociations(log, instanceID); err != nil {
+	// Get associations, retrying transient failures with jittered exponential backoff
+	if associations, err = p.listInstanceAssociationsWithRetry(log, instanceID, cancelFlag); err != nil {
 		out.MarkAsFailed(log, fmt.Errorf("failed to list instance associations, %v", err))
 		return
 	}
@@ -234,35 +301,25 @@ func (p *Plugin) runCommands(log log.T, pluginInput RefreshAssociationPluginInpu
 	// if user provided empty list or "" in the document, we will run all the associations now
 	applyAll := len(pluginInput.AssociationIds) == 0 || (len(pluginInput.AssociationIds) == 1 && pluginInput.AssociationIds[0] == "")
 
-	// read from cache or load association details from service
-	for _, assoc := range associations {
-		if err = p.assocSvc.LoadAssociationDetail(log, assoc); err != nil {
-			err = fmt.Errorf("Encountered error while loading association %v contents, %v",
-				*assoc.Association.AssociationId,
-				err)
-			p.assocSvc.UpdateInstanceAssociationStatus(
-				log,
-				*assoc.Association.AssociationId,
-				*assoc.Association.Name,
-				*assoc.Association.InstanceId,
-				contracts.AssociationStatusFailed,
-				contracts.AssociationErrorCodeListAssociationError,
-				times.ToIso8601UTC(time.Now()),
-				err.Error())
+	// read from cache or load association details from service, dispatching
+	// to a bounded worker pool so one instance's worth of associations
+	// doesn't load serially
+	out.AssociationResults, err = p.loadAssociationDetailsParallel(log, associations, pluginInput.AssociationIds, applyAll, pluginInput.DryRun, cancelFlag)
+
+	if pluginInput.DryRun {
+		// still render the preview even if some associations failed to
+		// load, so operators can see exactly which ones and why, instead of
+		// losing the whole report to a generic failure.
+		out.AppendInfo(log, buildDryRunReport(out.AssociationResults))
+		if err != nil {
 			out.MarkAsFailed(log, err)
-			return
 		}
+		return
+	}
 
-		if applyAll {
-			assoc.RunNow = true
-		} else {
-			for _, id := range pluginInput.AssociationIds {
-				if *assoc.Association.AssociationId == id {
-					assoc.RunNow = true
-					break
-				}
-			}
-		}
+	if err != nil {
+		out.MarkAsFailed(log, err)
+		return
 	}
 
 	schedulemanager.Refresh(log, associations, p.assocSvc)
@@ -277,3 +334,188 @@ func (p *Plugin) runCommands(log log.T, pluginInput RefreshAssociationPluginInpu
 
 	return
 }
+
+// buildDryRunReport renders which associations would execute or be skipped.
+func buildDryRunReport(results []AssociationRefreshResult) string {
+	var report bytes.Buffer
+	report.WriteString("Dry run: no associations were executed.\n")
+
+	report.WriteString("Would execute:\n")
+	for _, result := range results {
+		if !result.WouldExecute {
+			continue
+		}
+		report.WriteString(fmt.Sprintf("  - %v (%v) schedule=%q lastExecutionDate=%q\n",
+			result.AssociationId, result.Name, result.ScheduleExpression, result.LastExecutionDate))
+	}
+
+	report.WriteString("Would skip:\n")
+	for _, result := range results {
+		if result.WouldExecute {
+			continue
+		}
+		if result.Error != "" {
+			report.WriteString(fmt.Sprintf("  - %v (%v): failed to load, %v\n", result.AssociationId, result.Name, result.Error))
+			continue
+		}
+		report.WriteString(fmt.Sprintf("  - %v (%v): not in the requested AssociationIds\n", result.AssociationId, result.Name))
+	}
+
+	return report.String()
+}
+
+// listInstanceAssociationsWithRetry calls ListInstanceAssociations, retrying
+// transient errors with backoff, checking cancelFlag between attempts.
+func (p *Plugin) listInstanceAssociationsWithRetry(log log.T, instanceID string, cancelFlag task.CancelFlag) (associations []*model.InstanceAssociation, err error) {
+	strategy := &retry.ExponentialAttemptStrategy{
+		MinDelay: listAssociationsMinDelay,
+		MaxDelay: listAssociationsMaxDelay,
+		Deadline: listAssociationsRetryDeadline,
+	}
+
+	for strategy.Start(); strategy.HasNext(); strategy.Next() {
+		if cancelFlag.ShutDown() || cancelFlag.Canceled() {
+			return nil, errCancelled
+		}
+
+		if associations, err = p.assocSvc.ListInstanceAssociations(log, instanceID); err == nil || !retry.IsTransient(err) {
+			return associations, err
+		}
+
+		log.Infof("ListInstanceAssociations attempt %v failed with a transient error, retrying: %v", strategy.AttemptCount()+1, err)
+	}
+
+	return associations, err
+}
+
+// loadAssociationDetailWithRetry calls LoadAssociationDetail, retrying
+// transient errors the same way listInstanceAssociationsWithRetry does.
+func (p *Plugin) loadAssociationDetailWithRetry(log log.T, assoc *model.InstanceAssociation, cancelFlag task.CancelFlag) (err error) {
+	strategy := &retry.ExponentialAttemptStrategy{
+		MinDelay: listAssociationsMinDelay,
+		MaxDelay: listAssociationsMaxDelay,
+		Deadline: listAssociationsRetryDeadline,
+	}
+
+	for strategy.Start(); strategy.HasNext(); strategy.Next() {
+		if cancelFlag.ShutDown() || cancelFlag.Canceled() {
+			return errCancelled
+		}
+
+		if err = p.assocSvc.LoadAssociationDetail(log, assoc); err == nil || !retry.IsTransient(err) {
+			return err
+		}
+
+		log.Infof("LoadAssociationDetail attempt %v for association %v failed with a transient error, retrying: %v",
+			strategy.AttemptCount()+1, *assoc.Association.AssociationId, err)
+	}
+
+	return err
+}
+
+// clampWorkerCount bounds the worker pool to [1, jobCount].
+func clampWorkerCount(maxWorkers int, jobCount int) int {
+	workers := maxWorkers
+	if workers > jobCount {
+		workers = jobCount
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	return workers
+}
+
+// loadAssociationDetailsParallel loads every association's detail through a
+// bounded worker pool, marking each to run now per associationIds/applyAll
+// (unless dryRun is set), and waits for all workers to drain before
+// returning the per-association results and the first failure, if any.
+func (p *Plugin) loadAssociationDetailsParallel(log log.T, associations []*model.InstanceAssociation, associationIds []string, applyAll bool, dryRun bool, cancelFlag task.CancelFlag) ([]AssociationRefreshResult, error) {
+	jobs := make(chan *model.InstanceAssociation, len(associations))
+	for _, assoc := range associations {
+		jobs <- assoc
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make([]AssociationRefreshResult, 0, len(associations))
+	var failureCount int
+	var firstErr error
+
+	workers := clampWorkerCount(p.maxWorkers, len(associations))
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for assoc := range jobs {
+				start := time.Now()
+				loadErr := p.loadAssociationDetailWithRetry(log, assoc, cancelFlag)
+				result := AssociationRefreshResult{
+					AssociationId: *assoc.Association.AssociationId,
+					Name:          *assoc.Association.Name,
+					LoadDuration:  time.Since(start),
+				}
+
+				if loadErr == errCancelled {
+					// a shutdown/cancel request fired mid-pool: this
+					// association was never actually attempted or failed, so
+					// don't report it to the service as AssociationStatusFailed,
+					// just surface the cancellation to abort the run.
+					result.Error = loadErr.Error()
+				} else if loadErr != nil {
+					loadErr = fmt.Errorf("Encountered error while loading association %v contents, %v", result.AssociationId, loadErr)
+					result.Error = loadErr.Error()
+					p.assocSvc.UpdateInstanceAssociationStatus(
+						log,
+						result.AssociationId,
+						result.Name,
+						*assoc.Association.InstanceId,
+						contracts.AssociationStatusFailed,
+						contracts.AssociationErrorCodeListAssociationError,
+						times.ToIso8601UTC(time.Now()),
+						loadErr.Error())
+				} else {
+					if assoc.Association.ScheduleExpression != nil {
+						result.ScheduleExpression = *assoc.Association.ScheduleExpression
+					}
+					if assoc.Association.LastExecutionDate != nil {
+						result.LastExecutionDate = times.ToIso8601UTC(*assoc.Association.LastExecutionDate)
+					}
+
+					result.WouldExecute = applyAll
+					if !result.WouldExecute {
+						for _, id := range associationIds {
+							if result.AssociationId == id {
+								result.WouldExecute = true
+								break
+							}
+						}
+					}
+
+					if !dryRun && result.WouldExecute {
+						assoc.RunNow = true
+					}
+				}
+
+				mu.Lock()
+				if loadErr != nil {
+					failureCount++
+					if firstErr == nil {
+						firstErr = loadErr
+					}
+					if failureCount > maxReportedAssociationFailures {
+						mu.Unlock()
+						continue
+					}
+				}
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results, firstErr
+}
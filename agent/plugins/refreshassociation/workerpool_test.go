@@ -0,0 +1,28 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package refreshassociation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampWorkerCount(t *testing.T) {
+	assert.Equal(t, 1, clampWorkerCount(0, 10), "non-positive maxWorkers should still make progress")
+	assert.Equal(t, 1, clampWorkerCount(-1, 10))
+	assert.Equal(t, 4, clampWorkerCount(4, 10), "maxWorkers below the job count is used as-is")
+	assert.Equal(t, 3, clampWorkerCount(4, 3), "worker count should not exceed the number of jobs")
+	assert.Equal(t, 1, clampWorkerCount(4, 0), "zero jobs still returns at least one worker")
+}
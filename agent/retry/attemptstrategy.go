@@ -0,0 +1,85 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package retry implements a bounded, jittered exponential backoff retry
+// strategy for transient errors returned by downstream service calls.
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ExponentialAttemptStrategy is a jittered exponential backoff schedule:
+// delay = min(MaxDelay, MinDelay*2^(n-1)) for the n-th retry, plus up to
+// +/-25% jitter (so a capped delay can reach 1.25*MaxDelay), bounded by an
+// overall deadline. It is modeled on goamz's AttemptStrategy: callers drive
+// the loop themselves via HasNext/Next so they can interleave other checks
+// (e.g. a cancel flag or reboot request) between attempts instead of being
+// blocked inside a single call.
+type ExponentialAttemptStrategy struct {
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	Deadline time.Duration
+
+	start    time.Time
+	attempts int
+}
+
+// Start begins a new attempt sequence, resetting the deadline clock. Call it
+// once before the first HasNext/Next loop.
+func (s *ExponentialAttemptStrategy) Start() {
+	s.start = time.Now()
+	s.attempts = 0
+}
+
+// HasNext reports whether another attempt is allowed: the first attempt is
+// always allowed, and subsequent ones are allowed as long as the overall
+// deadline has not elapsed.
+func (s *ExponentialAttemptStrategy) HasNext() bool {
+	if s.attempts == 0 {
+		return true
+	}
+	return time.Since(s.start) < s.Deadline
+}
+
+// Next increments the attempt count, then sleeps for the backoff delay before
+// the upcoming attempt. Called once per loop iteration, so the very
+// first retry (the second overall attempt) still waits delay(1) rather than
+// firing back-to-back with the initial attempt.
+func (s *ExponentialAttemptStrategy) Next() {
+	s.attempts++
+	time.Sleep(s.delay(s.attempts))
+}
+
+// AttemptCount returns the number of Next calls so far in this sequence:
+// inside the loop body it is one less than the number of the current attempt.
+func (s *ExponentialAttemptStrategy) AttemptCount() int {
+	return s.attempts
+}
+
+// delay computes the backoff delay for the n-th retry: min(MaxDelay,
+// MinDelay*2^(n-1)), jittered by up to +/-25% so that many agents retrying the
+// same throttled call don't all wake up in lockstep.
+func (s *ExponentialAttemptStrategy) delay(n int) time.Duration {
+	base := s.MinDelay << uint(n-1)
+	if base <= 0 || base > s.MaxDelay {
+		base = s.MaxDelay
+	}
+
+	jitter := time.Duration(float64(base) * 0.25)
+	if jitter <= 0 {
+		return base
+	}
+	return base - jitter + time.Duration(rand.Int63n(int64(2*jitter)))
+}
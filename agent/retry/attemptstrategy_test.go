@@ -0,0 +1,86 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialAttemptStrategy_DrivesBackoffSchedule(t *testing.T) {
+	strategy := &ExponentialAttemptStrategy{
+		MinDelay: 20 * time.Millisecond,
+		MaxDelay: 200 * time.Millisecond,
+		Deadline: time.Second,
+	}
+
+	var gaps []time.Duration
+	last := time.Now()
+	attempts := 0
+	for strategy.Start(); strategy.HasNext() && attempts < 4; strategy.Next() {
+		now := time.Now()
+		if attempts > 0 {
+			gaps = append(gaps, now.Sub(last))
+		}
+		last = now
+		attempts++
+	}
+
+	if assert.Len(t, gaps, 3) {
+		// the first retry must wait roughly MinDelay, not fire back-to-back
+		// with the initial attempt.
+		assert.True(t, gaps[0] >= 14*time.Millisecond, "first retry fired too soon: %v", gaps[0])
+		// the second retry's delay should have roughly doubled.
+		assert.True(t, gaps[1] >= 25*time.Millisecond, "second retry delay did not grow: %v", gaps[1])
+	}
+}
+
+func TestExponentialAttemptStrategy_DelayIsBoundedByJitteredMaxDelay(t *testing.T) {
+	strategy := &ExponentialAttemptStrategy{
+		MinDelay: 10 * time.Millisecond,
+		MaxDelay: 50 * time.Millisecond,
+		Deadline: time.Minute,
+	}
+
+	// jitter is applied after capping at MaxDelay, so the bound is MaxDelay
+	// plus its 25% jitter.
+	bound := strategy.MaxDelay + strategy.MaxDelay/4
+	for n := 1; n <= 10; n++ {
+		d := strategy.delay(n)
+		assert.True(t, d < bound, "delay %v for attempt %v exceeded jittered MaxDelay %v", d, n, bound)
+		assert.True(t, d > 0)
+	}
+}
+
+func TestExponentialAttemptStrategy_DeadlineStopsRetries(t *testing.T) {
+	strategy := &ExponentialAttemptStrategy{
+		MinDelay: time.Millisecond,
+		MaxDelay: time.Millisecond,
+		Deadline: 20 * time.Millisecond,
+	}
+
+	strategy.Start()
+	attempts := 0
+	for strategy.HasNext() {
+		attempts++
+		strategy.Next()
+		if attempts > 1000 {
+			t.Fatal("HasNext never returned false; deadline was not honored")
+		}
+	}
+
+	assert.True(t, attempts > 1, "expected more than one attempt before the deadline elapsed")
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package retry
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// transientErrorCodes are the AWS error codes worth retrying: throttling and
+// request-limit responses from the service, rather than client-side
+// validation failures that will never succeed on retry.
+var transientErrorCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"Throttling":               true,
+	"RequestLimitExceeded":     true,
+	"TooManyRequestsException": true,
+	"RequestTimeout":           true,
+	"RequestTimeoutException":  true,
+	// RequestError is how aws-sdk-go surfaces a client-side network
+	// timeout/connection failure: no response was ever received, so it's
+	// never an awserr.RequestFailure with a status code.
+	"RequestError": true,
+}
+
+// IsTransient reports whether err is worth retrying: a network timeout, a
+// 5xx service response, or a recognized throttling error code. Anything else
+// (e.g. access denied, malformed input) is treated as terminal.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+
+	if transientErrorCodes[awsErr.Code()] {
+		return true
+	}
+
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= 500
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package retry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, IsTransient(nil))
+	assert.False(t, IsTransient(errors.New("not an aws error")))
+	assert.True(t, IsTransient(awserr.New("ThrottlingException", "slow down", nil)))
+	assert.True(t, IsTransient(awserr.New("RequestLimitExceeded", "slow down", nil)))
+	assert.False(t, IsTransient(awserr.New("AccessDenied", "nope", nil)))
+	assert.True(t, IsTransient(awserr.NewRequestFailure(
+		awserr.New("InternalError", "server error", nil), 500, "req-id")))
+	assert.False(t, IsTransient(awserr.NewRequestFailure(
+		awserr.New("BadRequest", "client error", nil), 400, "req-id")))
+
+	// a client-side network timeout never reaches the service, so it
+	// surfaces as a plain awserr.Error (code "RequestError"), not a
+	// RequestFailure with a status code.
+	assert.True(t, IsTransient(awserr.New("RequestError", "send request failed", errors.New("read: connection timed out"))))
+}